@@ -0,0 +1,212 @@
+package httperr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime"
+)
+
+// StackTracer is implemented by errors that can report the call stack at the point they
+// were created or wrapped.
+type StackTracer interface {
+	error
+	StackTrace() []uintptr
+}
+
+// Details wraps an error with arbitrary key/value fields and the stack captured at the
+// point of wrapping. Use [WithField] / [WithFields] to attach it.
+type Details struct {
+	error
+	fields map[string]any
+	stack  []uintptr
+}
+
+func (d *Details) Unwrap() error {
+	return d.error
+}
+
+// Fields returns the key/value pairs attached to this error.
+func (d *Details) Fields() map[string]any {
+	return d.fields
+}
+
+// StackTrace returns the call stack captured when this error was wrapped.
+func (d *Details) StackTrace() []uintptr {
+	return d.stack
+}
+
+// WithField attaches a single key/value pair to err. See [WithFields].
+func WithField(err error, key string, value any) error {
+	return WithFields(err, map[string]any{key: value})
+}
+
+// WithFields attaches key/value pairs to err, merging with any fields already attached via a
+// previous WithField/WithFields call further down the chain. The call stack is captured the
+// first time an error is wrapped; subsequent calls preserve it.
+func WithFields(err error, fields map[string]any) error {
+	merged := make(map[string]any, len(fields))
+	for k, v := range FieldsOf(err) {
+		merged[k] = v
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+
+	stack := StackTraceOf(err)
+	if stack == nil {
+		stack = captureStack(3)
+	}
+
+	return &Details{error: err, fields: merged, stack: stack}
+}
+
+// FieldsOf returns the fields attached to err via [WithField] / [WithFields], or nil if none
+// are attached anywhere in its chain.
+func FieldsOf(err error) map[string]any {
+	var d *Details
+	if errors.As(err, &d) {
+		return d.fields
+	}
+	return nil
+}
+
+// StackTraceOf returns the stack trace attached to err, or nil if none is attached anywhere
+// in its chain.
+func StackTraceOf(err error) []uintptr {
+	var st StackTracer
+	if errors.As(err, &st) {
+		return st.StackTrace()
+	}
+	return nil
+}
+
+func captureStack(skip int) []uintptr {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(skip, pcs)
+	return pcs[:n]
+}
+
+// PublicMessager is implemented by errors that distinguish an internal message from one that
+// is safe to send to clients. See [Public].
+type PublicMessager interface {
+	error
+	Public() string
+}
+
+type publicError struct {
+	error
+	message string
+}
+
+func (e *publicError) Unwrap() error {
+	return e.error
+}
+
+// Public returns the message that is safe to send to clients.
+func (e *publicError) Public() string {
+	return e.message
+}
+
+// Public wraps err, separating the internal message (err.Error(), which may contain
+// sensitive detail) from msg, the message that is safe to expose to clients.
+func Public(err error, msg string) error {
+	return &publicError{err, msg}
+}
+
+// PublicMessage returns the client-safe message for err. If err (or any error in its chain)
+// implements [PublicMessager], that message is returned; otherwise http.StatusText for err's
+// HTTP status is used, so the internal err.Error() is never leaked verbatim.
+func PublicMessage(err error) string {
+	var pm PublicMessager
+	if errors.As(err, &pm) {
+		return pm.Public()
+	}
+	return http.StatusText(HTTPStatus(err))
+}
+
+type debugErrorPayload struct {
+	Error  string         `json:"error"`
+	Status int            `json:"status"`
+	Chain  []string       `json:"chain,omitempty"`
+	Fields map[string]any `json:"fields,omitempty"`
+	Stack  []string       `json:"stack,omitempty"`
+}
+
+// DebugErrorWriter returns an ErrorWriter for local development that, when enabled, responds
+// with the full unwrapped error chain, attached fields, and a formatted stack trace instead
+// of the redacted payload [JsonErrorWriter] produces. When enabled is false it behaves exactly
+// like JsonErrorWriter, so callers can gate it behind a single config flag, e.g.:
+//
+//	mux := NewErrorServeMux(httperr.DebugErrorWriter(cfg.Debug))
+func DebugErrorWriter(enabled bool) ErrorWriter {
+	if !enabled {
+		return JsonErrorWriter
+	}
+
+	return func(w http.ResponseWriter, err error, code int) {
+		if handleHttpError(w, err, code, "application/json; charset=utf-8", encodeJSON) {
+			return
+		}
+
+		h := w.Header()
+		h.Del("Content-Length")
+		h.Set("Content-Type", "application/json; charset=utf-8")
+		h.Set("X-Content-Type-Options", "nosniff")
+		w.WriteHeader(code)
+		_ = json.NewEncoder(w).Encode(debugErrorPayload{
+			Error:  err.Error(),
+			Status: code,
+			Chain:  unwrapChain(err),
+			Fields: FieldsOf(err),
+			Stack:  formatStack(StackTraceOf(err)),
+		})
+	}
+}
+
+// unwrapChain walks err's chain and describes what each layer itself contributes. None of this
+// package's wrapper types override Error() (see [Public]'s doc comment on why that message must
+// stay the untouched, innermost one), so calling err.Error() at every layer would repeat the
+// same terminal message once per wrapper. describeChainLayer reports the layer's own annotation
+// instead, falling back to err.Error() for the innermost, unwrapped error.
+func unwrapChain(err error) []string {
+	chain := make([]string, 0, 4)
+	for err != nil {
+		chain = append(chain, describeChainLayer(err))
+		err = errors.Unwrap(err)
+	}
+	return chain
+}
+
+func describeChainLayer(err error) string {
+	switch e := err.(type) {
+	case *statusError:
+		return fmt.Sprintf("status: %d", e.status)
+	case *problemError:
+		return fmt.Sprintf("problem: %s", e.problem.Title)
+	case *publicError:
+		return fmt.Sprintf("public: %q", e.message)
+	case *Details:
+		return fmt.Sprintf("fields: %v", e.fields)
+	default:
+		return err.Error()
+	}
+}
+
+func formatStack(pcs []uintptr) []string {
+	if len(pcs) == 0 {
+		return nil
+	}
+
+	frames := runtime.CallersFrames(pcs)
+	formatted := make([]string, 0, len(pcs))
+	for {
+		frame, more := frames.Next()
+		formatted = append(formatted, fmt.Sprintf("%s\n\t%s:%d", frame.Function, frame.File, frame.Line))
+		if !more {
+			break
+		}
+	}
+	return formatted
+}
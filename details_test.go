@@ -0,0 +1,109 @@
+package httperr
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithFields(t *testing.T) {
+	a := assert.New(t)
+
+	err := WithField(errors.New("insert failed"), "table", "users")
+	err = WithField(err, "row", 42)
+
+	fields := FieldsOf(err)
+	a.Equal("users", fields["table"])
+	a.Equal(42, fields["row"])
+	a.NotEmpty(StackTraceOf(err))
+}
+
+func TestFieldsOfNoFields(t *testing.T) {
+	a := assert.New(t)
+	a.Nil(FieldsOf(errors.New("plain")))
+}
+
+func TestPublic(t *testing.T) {
+	a := assert.New(t)
+
+	err := Public(errors.New("constraint violation on users.email"), "that email is already registered")
+	a.Equal("that email is already registered", PublicMessage(err))
+	a.Equal("constraint violation on users.email", err.Error())
+}
+
+func TestPublicMessageFallback(t *testing.T) {
+	a := assert.New(t)
+	a.Equal("Internal Server Error", PublicMessage(errors.New("boom")))
+}
+
+func TestStatusErrorIsPublic(t *testing.T) {
+	a := assert.New(t)
+
+	a.Equal("boom", PublicMessage(WithStatus(errors.New("boom"), http.StatusBadRequest)))
+	a.Equal("invalid email format", PublicMessage(New("invalid email format", http.StatusBadRequest)))
+}
+
+func TestDebugErrorWriter(t *testing.T) {
+	a := assert.New(t)
+
+	t.Run("disabled behaves like JsonErrorWriter", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+		DebugErrorWriter(false)(rw, errors.New("standard error"), http.StatusInternalServerError)
+		a.Equal(`{"error":"Internal Server Error","status":500}`+"\n", rw.Body.String())
+	})
+
+	t.Run("enabled includes chain, fields and stack", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+		err := WithField(Public(errors.New("duplicate key"), "conflict"), "table", "users")
+
+		DebugErrorWriter(true)(rw, err, http.StatusConflict)
+
+		var decoded map[string]any
+		a.NoError(json.Unmarshal(rw.Body.Bytes(), &decoded))
+		a.Equal("duplicate key", decoded["error"])
+		a.Equal("users", decoded["fields"].(map[string]any)["table"])
+		a.NotEmpty(decoded["chain"])
+		a.NotEmpty(decoded["stack"])
+	})
+
+	t.Run("chain entries are distinct per layer, not duplicated", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+		err := WithField(Public(errors.New("duplicate key"), "conflict"), "table", "users")
+
+		DebugErrorWriter(true)(rw, err, http.StatusConflict)
+
+		var decoded map[string]any
+		a.NoError(json.Unmarshal(rw.Body.Bytes(), &decoded))
+
+		chain, ok := decoded["chain"].([]any)
+		a.True(ok)
+		a.Len(chain, 3)
+
+		seen := make(map[string]bool, len(chain))
+		for _, entry := range chain {
+			seen[entry.(string)] = true
+		}
+		a.Len(seen, 3, "each layer should contribute its own description instead of the duplicated terminal message")
+	})
+
+	t.Run("honors HttpError headers and body", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+		err := &HttpError{
+			Status:  http.StatusTooManyRequests,
+			Headers: http.Header{"Retry-After": []string{"30"}},
+			Body:    Problem{Title: "slow down", Status: http.StatusTooManyRequests},
+		}
+
+		DebugErrorWriter(true)(rw, err, HTTPStatus(err))
+
+		a.Equal("30", rw.Header().Get("Retry-After"))
+
+		var decoded map[string]any
+		a.NoError(json.Unmarshal(rw.Body.Bytes(), &decoded))
+		a.Equal("slow down", decoded["title"])
+	})
+}
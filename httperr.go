@@ -12,6 +12,17 @@ type ErrorHandlerFunc func(w http.ResponseWriter, r *http.Request) error
 // ErrorWriter defines a function which is reply to the request with a specific error message.
 type ErrorWriter func(w http.ResponseWriter, err error, code int)
 
+// ErrorWriterRequest is like ErrorWriter but also receives the originating *http.Request,
+// allowing the written response to vary based on request headers (e.g. content negotiation).
+type ErrorWriterRequest func(w http.ResponseWriter, r *http.Request, err error, code int)
+
+// adaptErrorWriter lets an ErrorWriter be used wherever an ErrorWriterRequest is expected.
+func adaptErrorWriter(ew ErrorWriter) ErrorWriterRequest {
+	return func(w http.ResponseWriter, _ *http.Request, err error, code int) {
+		ew(w, err, code)
+	}
+}
+
 // HttpStatusError is an error type which embeds HTTP status information for responding.
 type HttpStatusError interface {
 	error
@@ -26,34 +37,52 @@ func StdHandler(f ErrorHandlerFunc) http.HandlerFunc {
 // StdHandlerWithError converts an ErrorHandlerFunc into the standard library http.HandlerFunc.
 // Whilst also given the freedom to write the error result as preferred.
 func StdHandlerWithError(f ErrorHandlerFunc, errorWriter ErrorWriter) http.HandlerFunc {
+	return StdHandlerWithRequestError(f, adaptErrorWriter(errorWriter))
+}
+
+// StdHandlerWithRequestError is like StdHandlerWithError but threads the *http.Request
+// through to the ErrorWriterRequest, e.g. for content negotiation via [NegotiatingErrorWriter].
+func StdHandlerWithRequestError(f ErrorHandlerFunc, errorWriter ErrorWriterRequest) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		err := f(w, r)
 		if err != nil {
-			errorWriter(w, err, HTTPStatus(err))
+			errorWriter(w, r, err, HTTPStatus(err))
 		}
 	}
 }
 
 // StdErrorWriter is the default http.Error implementation that will be used to write the error.
 func StdErrorWriter(w http.ResponseWriter, err error, code int) {
+	if handleHttpError(w, err, code, "text/plain; charset=utf-8", encodeText) {
+		return
+	}
+
 	http.Error(w, err.Error(), code)
 }
 
 type jsonErrorPayload struct {
-	Error  string `json:"error"`
-	Status int    `json:"status"`
+	Error  string         `json:"error"`
+	Status int            `json:"status"`
+	Fields map[string]any `json:"fields,omitempty"`
 }
 
-// JsonErrorWriter will write a JSON error response
+// JsonErrorWriter will write a JSON error response.
+// Only the public-safe message (see [Public]) and any fields attached via [WithField] are
+// included; use [DebugErrorWriter] during local development to see the full error chain.
 func JsonErrorWriter(w http.ResponseWriter, err error, code int) {
+	if handleHttpError(w, err, code, "application/json; charset=utf-8", encodeJSON) {
+		return
+	}
+
 	h := w.Header()
 	h.Del("Content-Length")
 	h.Set("Content-Type", "application/json; charset=utf-8")
 	h.Set("X-Content-Type-Options", "nosniff")
 	w.WriteHeader(code)
 	_ = json.NewEncoder(w).Encode(jsonErrorPayload{
-		Error:  err.Error(),
+		Error:  PublicMessage(err),
 		Status: code,
+		Fields: FieldsOf(err),
 	})
 }
 
@@ -66,14 +95,38 @@ func (e statusError) StatusCode() int {
 	return e.status
 }
 
+func (e statusError) Unwrap() error {
+	return e.error
+}
+
+// Public implements [PublicMessager]. WithStatus and New predate the introduction of
+// PublicMessager, back when the message passed to them was always shown to the client
+// verbatim; returning it here preserves that original contract instead of having it silently
+// replaced by http.StatusText(code). If the wrapped error itself carries a public message
+// (e.g. via [Public]), that message is returned instead.
+func (e statusError) Public() string {
+	var pm PublicMessager
+	if errors.As(e.error, &pm) {
+		return pm.Public()
+	}
+	return e.Error()
+}
+
 // WithStatus embeds an HTTP status code to the original error.
 // When caught by the handler it will use the HTTP status in the response writing.
+//
+// The wrapped err's message is treated as public (see [PublicMessager]) and is included as-is
+// by [JsonErrorWriter] and friends. Use [Public] instead, or on err before calling WithStatus,
+// if err.Error() carries internal detail that shouldn't reach clients.
 func WithStatus(err error, code int) error {
 	return &statusError{err, code}
 }
 
 // New creates a new error with a custom HTTP status code.
 // When caught by the handler it will use the HTTP status in the response writing.
+//
+// The message is treated as public (see [PublicMessager]) and is sent to clients as-is by
+// [JsonErrorWriter] and friends, so it must never contain internal detail.
 func New(err string, code int) error {
 	return &statusError{errors.New(err), code}
 }
@@ -81,6 +134,16 @@ func New(err string, code int) error {
 // HTTPStatus extracts an HTTP status code from err, if available.
 // If err implements HttpStatusError we will return the embedded HTTP status code.
 // Otherwise, http.StatusInternalServerError is returned.
+//
+// When err wraps more than one HttpStatusError (e.g. a status applied on top of one already
+// present deeper in the chain), the outermost one wins, since errors.As walks from err inward
+// and stops at the first match: the status closest to where the error is ultimately handled
+// takes precedence over one set further down the call stack.
+//
+// HTTPStatus never consults a [StatusMapper] — it's a stateless package function with nothing
+// to hold one. Use [HTTPStatusWithMapper] to also fall back to sentinel/type-based mappings
+// before defaulting to 500; [ErrorServeMux] does this automatically when constructed with
+// [WithMapper].
 func HTTPStatus(err error) int {
 	if err == nil {
 		return 0
@@ -98,22 +161,79 @@ func HTTPStatus(err error) int {
 // with the ErrorHandlerFunc.
 type ErrorServeMux struct {
 	mux         *http.ServeMux
-	errorWriter ErrorWriter
+	errorWriter ErrorWriterRequest
+	middleware  []Middleware
+	mapper      *StatusMapper
+}
+
+// ErrorServeMuxOption configures an [ErrorServeMux] constructed via NewErrorServeMux or
+// NewErrorServeMuxWithRequest.
+type ErrorServeMuxOption func(*ErrorServeMux)
+
+// WithMapper configures the ErrorServeMux to consult mapper when resolving the HTTP status for
+// an error that doesn't already implement HttpStatusError, so handlers can return data-layer
+// errors (e.g. sql.ErrNoRows) as-is instead of wrapping every one with WithStatus.
+func WithMapper(mapper *StatusMapper) ErrorServeMuxOption {
+	return func(m *ErrorServeMux) {
+		m.mapper = mapper
+	}
 }
 
 // NewErrorServeMux allocates and returns a new [ErrorServeMux].
-func NewErrorServeMux(ew ErrorWriter) *ErrorServeMux {
-	return &ErrorServeMux{
+func NewErrorServeMux(ew ErrorWriter, opts ...ErrorServeMuxOption) *ErrorServeMux {
+	return NewErrorServeMuxWithRequest(adaptErrorWriter(ew), opts...)
+}
+
+// NewErrorServeMuxWithRequest allocates and returns a new [ErrorServeMux] using a
+// request-aware ErrorWriterRequest, e.g. for content negotiation via [NegotiatingErrorWriter].
+func NewErrorServeMuxWithRequest(ew ErrorWriterRequest, opts ...ErrorServeMuxOption) *ErrorServeMux {
+	m := &ErrorServeMux{
 		mux:         http.NewServeMux(),
 		errorWriter: ew,
 	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// status resolves the HTTP status code for err via [HTTPStatusWithMapper], using the mux's
+// configured StatusMapper, if any.
+func (m *ErrorServeMux) status(err error) int {
+	return HTTPStatusWithMapper(err, m.mapper)
+}
+
+// Use registers middleware that wraps every handler subsequently registered via HandleFunc
+// or Handle. Middleware runs in registration order, outermost first.
+func (m *ErrorServeMux) Use(mw ...Middleware) {
+	m.middleware = append(m.middleware, mw...)
 }
 
 // HandleFunc registers the handler function for the given pattern.
 // If the given pattern conflicts, with one that is already registered, HandleFunc panics.
 // It will register the ErrorHandlerFunc with the ErrorWriter configured in the ErrorServeMux.
 func (m *ErrorServeMux) HandleFunc(pattern string, handler ErrorHandlerFunc) {
-	m.mux.HandleFunc(pattern, StdHandlerWithError(handler, m.errorWriter))
+	m.Handle(pattern, handler)
+}
+
+// Handle registers handler for the given pattern, wrapped by the mux's global middleware
+// (registered via Use) followed by any per-route mw, in registration order.
+// If the given pattern conflicts with one that is already registered, Handle panics.
+func (m *ErrorServeMux) Handle(pattern string, handler ErrorHandlerFunc, mw ...Middleware) {
+	chained := make([]Middleware, 0, len(m.middleware)+len(mw))
+	chained = append(chained, m.middleware...)
+	chained = append(chained, mw...)
+
+	for i := len(chained) - 1; i >= 0; i-- {
+		handler = chained[i](handler)
+	}
+
+	m.mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+		err := handler(w, r)
+		if err != nil {
+			m.errorWriter(w, r, err, m.status(err))
+		}
+	})
 }
 
 // ServeHTTP dispatches the request to the handler whose
@@ -82,14 +82,35 @@ func TestStdErrorWriter(t *testing.T) {
 
 func TestJsonErrorWriter(t *testing.T) {
 	a := assert.New(t)
-	err := errors.New("standard error")
-	rw := httptest.NewRecorder()
 
-	JsonErrorWriter(rw, err, http.StatusInternalServerError)
-	a.Equal(http.StatusInternalServerError, rw.Result().StatusCode)
-	a.Equal("application/json; charset=utf-8", rw.Header().Get("Content-Type"))
-	a.Equal("nosniff", rw.Header().Get("X-Content-Type-Options"))
-	a.Equal(`{"error":"standard error","status":500}`+"\n", rw.Body.String())
+	t.Run("plain error is redacted", func(t *testing.T) {
+		err := errors.New("standard error")
+		rw := httptest.NewRecorder()
+
+		JsonErrorWriter(rw, err, http.StatusInternalServerError)
+		a.Equal(http.StatusInternalServerError, rw.Result().StatusCode)
+		a.Equal("application/json; charset=utf-8", rw.Header().Get("Content-Type"))
+		a.Equal("nosniff", rw.Header().Get("X-Content-Type-Options"))
+		a.Equal(`{"error":"Internal Server Error","status":500}`+"\n", rw.Body.String())
+	})
+
+	t.Run("public message and fields are included", func(t *testing.T) {
+		err := WithField(Public(errors.New("duplicate key 'email'"), "email already in use"), "field", "email")
+		rw := httptest.NewRecorder()
+
+		JsonErrorWriter(rw, err, http.StatusConflict)
+		a.Equal(`{"error":"email already in use","status":409,"fields":{"field":"email"}}`+"\n", rw.Body.String())
+	})
+
+	t.Run("message from New and WithStatus is preserved, not replaced", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+		JsonErrorWriter(rw, New("invalid email format", http.StatusBadRequest), http.StatusBadRequest)
+		a.Equal(`{"error":"invalid email format","status":400}`+"\n", rw.Body.String())
+
+		rw = httptest.NewRecorder()
+		JsonErrorWriter(rw, WithStatus(errors.New("invalid email format"), http.StatusBadRequest), http.StatusBadRequest)
+		a.Equal(`{"error":"invalid email format","status":400}`+"\n", rw.Body.String())
+	})
 }
 
 func TestErrorServeMux(t *testing.T) {
@@ -0,0 +1,83 @@
+package httperr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+)
+
+// HttpError lets an error fully describe the HTTP response it should produce, instead of an
+// ErrorWriter only ever seeing err.Error() and a status code.
+type HttpError struct {
+	// Status is the HTTP status code to respond with.
+	Status int
+
+	// Headers are merged into the response before it is written, e.g. Retry-After.
+	Headers http.Header
+
+	// Body, when set, is marshaled by the ErrorWriter in place of its usual payload.
+	Body any
+
+	// WriteTo, when set, takes full control of writing the response, bypassing Body and the
+	// ErrorWriter's usual encoding entirely. Headers are still merged beforehand.
+	WriteTo func(w http.ResponseWriter) error
+}
+
+func (e *HttpError) Error() string {
+	if e.Body != nil {
+		return fmt.Sprintf("%v", e.Body)
+	}
+	return http.StatusText(e.Status)
+}
+
+// StatusCode implements HttpStatusError.
+func (e *HttpError) StatusCode() int {
+	return e.Status
+}
+
+// handleHttpError checks whether err wraps an *HttpError and, if so, merges its Headers into
+// w and either delegates to its WriteTo escape hatch or encodes its Body via encode, writing
+// contentType and code. It reports whether it fully handled the response.
+func handleHttpError(w http.ResponseWriter, err error, code int, contentType string, encode func(w http.ResponseWriter, body any)) bool {
+	var he *HttpError
+	if !errors.As(err, &he) {
+		return false
+	}
+
+	for k, vs := range he.Headers {
+		for _, v := range vs {
+			w.Header().Add(k, v)
+		}
+	}
+
+	if he.WriteTo != nil {
+		_ = he.WriteTo(w)
+		return true
+	}
+
+	if he.Body != nil {
+		h := w.Header()
+		h.Del("Content-Length")
+		h.Set("Content-Type", contentType)
+		h.Set("X-Content-Type-Options", "nosniff")
+		w.WriteHeader(code)
+		encode(w, he.Body)
+		return true
+	}
+
+	return false
+}
+
+func encodeJSON(w http.ResponseWriter, body any) {
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func encodeText(w http.ResponseWriter, body any) {
+	_, _ = fmt.Fprintf(w, "%v\n", body)
+}
+
+func encodeHTML(w http.ResponseWriter, body any) {
+	_, _ = fmt.Fprintf(w, "<p>%s</p>\n", html.EscapeString(fmt.Sprintf("%v", body)))
+}
@@ -0,0 +1,68 @@
+package httperr
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHttpError(t *testing.T) {
+	a := assert.New(t)
+
+	err := &HttpError{
+		Status:  http.StatusTooManyRequests,
+		Headers: http.Header{"Retry-After": []string{"30"}},
+		Body:    Problem{Title: "slow down", Status: http.StatusTooManyRequests},
+	}
+
+	a.Equal(http.StatusTooManyRequests, HTTPStatus(err))
+
+	rw := httptest.NewRecorder()
+	JsonErrorWriter(rw, err, HTTPStatus(err))
+
+	a.Equal(http.StatusTooManyRequests, rw.Result().StatusCode)
+	a.Equal("30", rw.Header().Get("Retry-After"))
+	a.Equal("application/json; charset=utf-8", rw.Header().Get("Content-Type"))
+
+	var decoded map[string]any
+	a.NoError(json.Unmarshal(rw.Body.Bytes(), &decoded))
+	a.Equal("slow down", decoded["title"])
+}
+
+func TestHttpErrorWriteTo(t *testing.T) {
+	a := assert.New(t)
+
+	called := false
+	err := &HttpError{
+		Status:  http.StatusServiceUnavailable,
+		Headers: http.Header{"Retry-After": []string{"5"}},
+		WriteTo: func(w http.ResponseWriter) error {
+			called = true
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, werr := w.Write([]byte("try again later"))
+			return werr
+		},
+	}
+
+	rw := httptest.NewRecorder()
+	StdErrorWriter(rw, err, HTTPStatus(err))
+
+	a.True(called)
+	a.Equal(http.StatusServiceUnavailable, rw.Result().StatusCode)
+	a.Equal("5", rw.Header().Get("Retry-After"))
+	a.Equal("try again later", rw.Body.String())
+}
+
+func TestHttpErrorWithoutBody(t *testing.T) {
+	a := assert.New(t)
+
+	err := &HttpError{Status: http.StatusForbidden}
+	a.Equal("Forbidden", err.Error())
+
+	rw := httptest.NewRecorder()
+	StdErrorWriter(rw, err, HTTPStatus(err))
+	a.Equal(http.StatusForbidden, rw.Result().StatusCode)
+}
@@ -0,0 +1,93 @@
+package httperr
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"os"
+)
+
+type mapperRule struct {
+	match func(err error) bool
+	code  int
+}
+
+// StatusMapper is a registry of rules for deriving an HTTP status code from an error that
+// doesn't itself carry one, so data-layer calls can `return err` as-is instead of wrapping
+// every one with [WithStatus]. See [WithMapper].
+type StatusMapper struct {
+	rules []mapperRule
+}
+
+// NewStatusMapper allocates and returns an empty [StatusMapper].
+func NewStatusMapper() *StatusMapper {
+	return &StatusMapper{}
+}
+
+// Map registers code for any error matching target via errors.Is. Returns the mapper so calls
+// can be chained.
+func (m *StatusMapper) Map(target error, code int) *StatusMapper {
+	return m.MapFunc(func(err error) bool {
+		return errors.Is(err, target)
+	}, code)
+}
+
+// MapFunc registers code for any error matching an arbitrary predicate, e.g. one built around
+// errors.As for a custom validation error type:
+//
+//	mapper.MapFunc(func(err error) bool {
+//		var ve *ValidationError
+//		return errors.As(err, &ve)
+//	}, http.StatusUnprocessableEntity)
+func (m *StatusMapper) MapFunc(match func(err error) bool, code int) *StatusMapper {
+	m.rules = append(m.rules, mapperRule{match: match, code: code})
+	return m
+}
+
+// Status returns the status code registered for err and true, or 0 and false if no rule
+// matches. Rules are consulted in registration order; the first match wins.
+func (m *StatusMapper) Status(err error) (int, bool) {
+	for _, rule := range m.rules {
+		if rule.match(err) {
+			return rule.code, true
+		}
+	}
+	return 0, false
+}
+
+// DefaultStatusMapper returns a [StatusMapper] pre-populated with mappings for common stdlib
+// sentinel errors.
+func DefaultStatusMapper() *StatusMapper {
+	return NewStatusMapper().
+		Map(sql.ErrNoRows, http.StatusNotFound).
+		Map(os.ErrNotExist, http.StatusNotFound).
+		Map(os.ErrPermission, http.StatusForbidden).
+		Map(context.DeadlineExceeded, http.StatusGatewayTimeout).
+		Map(context.Canceled, http.StatusRequestTimeout)
+}
+
+// HTTPStatusWithMapper is like [HTTPStatus], but consults mapper before falling back to
+// http.StatusInternalServerError, so a data-layer error such as sql.ErrNoRows resolves to its
+// mapped status even though it doesn't implement HttpStatusError. An embedded HttpStatusError
+// still always wins over a mapper rule. [ErrorServeMux] does this automatically for every
+// handler when constructed with [WithMapper]; use this directly when resolving a status
+// outside of an ErrorServeMux, e.g. before calling [StdHandlerWithRequestError].
+func HTTPStatusWithMapper(err error, mapper *StatusMapper) int {
+	if err == nil {
+		return 0
+	}
+
+	var hse HttpStatusError
+	if errors.As(err, &hse) {
+		return hse.StatusCode()
+	}
+
+	if mapper != nil {
+		if code, ok := mapper.Status(err); ok {
+			return code
+		}
+	}
+
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,84 @@
+package httperr
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatusMapper(t *testing.T) {
+	a := assert.New(t)
+
+	mapper := NewStatusMapper().Map(sql.ErrNoRows, http.StatusNotFound)
+
+	code, ok := mapper.Status(sql.ErrNoRows)
+	a.True(ok)
+	a.Equal(http.StatusNotFound, code)
+
+	_, ok = mapper.Status(errors.New("unrelated"))
+	a.False(ok)
+}
+
+func TestStatusMapperFunc(t *testing.T) {
+	a := assert.New(t)
+
+	type validationError struct{ error }
+	mapper := NewStatusMapper().MapFunc(func(err error) bool {
+		var ve *validationError
+		return errors.As(err, &ve)
+	}, http.StatusUnprocessableEntity)
+
+	code, ok := mapper.Status(&validationError{errors.New("bad input")})
+	a.True(ok)
+	a.Equal(http.StatusUnprocessableEntity, code)
+}
+
+func TestDefaultStatusMapper(t *testing.T) {
+	a := assert.New(t)
+
+	mapper := DefaultStatusMapper()
+	code, ok := mapper.Status(sql.ErrNoRows)
+	a.True(ok)
+	a.Equal(http.StatusNotFound, code)
+}
+
+func TestHTTPStatusWithMapper(t *testing.T) {
+	a := assert.New(t)
+
+	mapper := DefaultStatusMapper()
+	a.Equal(http.StatusNotFound, HTTPStatusWithMapper(sql.ErrNoRows, mapper))
+	a.Equal(http.StatusInternalServerError, HTTPStatusWithMapper(errors.New("unrelated"), mapper))
+	a.Equal(http.StatusInternalServerError, HTTPStatusWithMapper(sql.ErrNoRows, nil))
+	a.Equal(http.StatusTeapot, HTTPStatusWithMapper(WithStatus(sql.ErrNoRows, http.StatusTeapot), mapper))
+	a.Equal(0, HTTPStatusWithMapper(nil, mapper))
+}
+
+func TestErrorServeMuxWithMapper(t *testing.T) {
+	a := assert.New(t)
+
+	mux := NewErrorServeMux(StdErrorWriter, WithMapper(DefaultStatusMapper()))
+	mux.HandleFunc("GET /missing", func(w http.ResponseWriter, r *http.Request) error {
+		return sql.ErrNoRows
+	})
+	mux.HandleFunc("GET /explicit", func(w http.ResponseWriter, r *http.Request) error {
+		return WithStatus(sql.ErrNoRows, http.StatusTeapot)
+	})
+
+	t.Run("mapped via StatusMapper", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/missing", nil)
+		rw := httptest.NewRecorder()
+		mux.ServeHTTP(rw, req)
+		a.Equal(http.StatusNotFound, rw.Result().StatusCode)
+	})
+
+	t.Run("explicit status wins over mapper", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/explicit", nil)
+		rw := httptest.NewRecorder()
+		mux.ServeHTTP(rw, req)
+		a.Equal(http.StatusTeapot, rw.Result().StatusCode)
+	})
+}
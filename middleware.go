@@ -0,0 +1,169 @@
+package httperr
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Middleware wraps an ErrorHandlerFunc with additional behaviour. Middlewares compose around
+// the handler in registration order: the first middleware registered runs outermost.
+type Middleware func(ErrorHandlerFunc) ErrorHandlerFunc
+
+// Recover returns a [Middleware] that recovers panics raised by the wrapped handler and
+// converts them into an error carrying http.StatusInternalServerError, which the mux's
+// configured ErrorWriter then renders.
+func Recover() Middleware {
+	return func(next ErrorHandlerFunc) ErrorHandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) (err error) {
+			defer func() {
+				if rec := recover(); rec != nil {
+					err = WithStatus(fmt.Errorf("panic: %v", rec), http.StatusInternalServerError)
+				}
+			}()
+
+			return next(w, r)
+		}
+	}
+}
+
+// Timeout returns a [Middleware] that cancels the request context after d and, if the handler
+// has not returned by then, writes ctx.Err() via ew (StdErrorWriter if ew is omitted) with
+// http.StatusGatewayTimeout.
+//
+// next runs in its own goroutine so that Timeout can respond as soon as the deadline passes,
+// even if next ignores ctx.Done() and keeps running. next may then still be writing to w
+// concurrently with the timeout response being written, so w is wrapped in a
+// [timeoutResponseWriter]: writing the timeout response and discarding a write from next are
+// done under the same lock, so exactly one of the two ever reaches the underlying
+// http.ResponseWriter, never both, and never racing.
+func Timeout(d time.Duration, ew ...ErrorWriter) Middleware {
+	writer := StdErrorWriter
+	if len(ew) > 0 {
+		writer = ew[0]
+	}
+
+	return func(next ErrorHandlerFunc) ErrorHandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) error {
+			ctx, cancel := context.WithTimeout(r.Context(), d)
+			defer cancel()
+			r = r.WithContext(ctx)
+
+			tw := newTimeoutResponseWriter(w)
+
+			done := make(chan error, 1)
+			go func() {
+				done <- next(tw, r)
+			}()
+
+			select {
+			case err := <-done:
+				return err
+			case <-ctx.Done():
+				tw.writeTimeout(writer, WithStatus(ctx.Err(), http.StatusGatewayTimeout))
+				return nil
+			}
+		}
+	}
+}
+
+// timeoutResponseWriter buffers headers written by a handler running in a separate goroutine
+// and only forwards them to the underlying http.ResponseWriter if the handler commits them
+// before writeTimeout does. This mirrors the approach net/http.TimeoutHandler uses to make the
+// "loser" of a timeout race harmless instead of racing on the shared http.ResponseWriter.
+type timeoutResponseWriter struct {
+	http.ResponseWriter
+	mu          sync.Mutex
+	header      http.Header
+	wroteHeader bool
+	timedOut    bool
+}
+
+func newTimeoutResponseWriter(w http.ResponseWriter) *timeoutResponseWriter {
+	return &timeoutResponseWriter{ResponseWriter: w, header: make(http.Header)}
+}
+
+// Header returns a private header map; it is only copied onto the real ResponseWriter's
+// headers once WriteHeader commits, so mutating it after a timeout has no visible effect.
+func (w *timeoutResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *timeoutResponseWriter) WriteHeader(code int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.writeHeaderLocked(code)
+}
+
+func (w *timeoutResponseWriter) writeHeaderLocked(code int) {
+	if w.timedOut || w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+
+	dst := w.ResponseWriter.Header()
+	for k, v := range w.header {
+		dst[k] = v
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *timeoutResponseWriter) Write(b []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.writeHeaderLocked(http.StatusOK)
+	if w.timedOut {
+		return len(b), nil
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// writeTimeout writes err via ew, unless the handler already committed a response first. It
+// takes the same lock as WriteHeader/Write, so it either runs before the handler writes
+// anything (in which case the handler's writes are discarded from then on) or is itself a
+// no-op because the handler already won the race — the two can never interleave.
+func (w *timeoutResponseWriter) writeTimeout(ew ErrorWriter, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.wroteHeader {
+		return
+	}
+	w.timedOut = true
+	ew(w.ResponseWriter, err, HTTPStatus(err))
+}
+
+type requestIDKeyType struct{}
+
+var requestIDKey requestIDKeyType
+
+// RequestID returns a [Middleware] that generates a request ID, attaches it to the request
+// context (retrievable via RequestIDFromContext) and echoes it back via the X-Request-Id
+// header on both successful and error responses.
+func RequestID() Middleware {
+	return func(next ErrorHandlerFunc) ErrorHandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) error {
+			id := newRequestID()
+			w.Header().Set("X-Request-Id", id)
+			r = r.WithContext(context.WithValue(r.Context(), requestIDKey, id))
+			return next(w, r)
+		}
+	}
+}
+
+// RequestIDFromContext returns the request ID attached by RequestID, if any.
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(requestIDKey).(string)
+	return id, ok
+}
+
+func newRequestID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
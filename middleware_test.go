@@ -0,0 +1,118 @@
+package httperr
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorServeMuxUse(t *testing.T) {
+	a := assert.New(t)
+
+	var order []string
+	track := func(name string) Middleware {
+		return func(next ErrorHandlerFunc) ErrorHandlerFunc {
+			return func(w http.ResponseWriter, r *http.Request) error {
+				order = append(order, name)
+				return next(w, r)
+			}
+		}
+	}
+
+	mux := NewErrorServeMux(StdErrorWriter)
+	mux.Use(track("global"))
+	mux.Handle("GET /route", func(w http.ResponseWriter, r *http.Request) error {
+		order = append(order, "handler")
+		return nil
+	}, track("route"))
+
+	req := httptest.NewRequest(http.MethodGet, "/route", nil)
+	rw := httptest.NewRecorder()
+	mux.ServeHTTP(rw, req)
+
+	a.Equal([]string{"global", "route", "handler"}, order)
+}
+
+func TestRecover(t *testing.T) {
+	a := assert.New(t)
+
+	mux := NewErrorServeMux(StdErrorWriter)
+	mux.Use(Recover())
+	mux.HandleFunc("GET /panic", func(w http.ResponseWriter, r *http.Request) error {
+		panic("boom")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/panic", nil)
+	rw := httptest.NewRecorder()
+	mux.ServeHTTP(rw, req)
+
+	a.Equal(http.StatusInternalServerError, rw.Result().StatusCode)
+}
+
+func TestTimeout(t *testing.T) {
+	a := assert.New(t)
+
+	mux := NewErrorServeMux(StdErrorWriter)
+	mux.Use(Timeout(10 * time.Millisecond))
+	mux.HandleFunc("GET /slow", func(w http.ResponseWriter, r *http.Request) error {
+		<-r.Context().Done()
+		return errors.New("should not be reached")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/slow", nil)
+	rw := httptest.NewRecorder()
+	mux.ServeHTTP(rw, req)
+
+	a.Equal(http.StatusGatewayTimeout, rw.Result().StatusCode)
+}
+
+func TestTimeoutDiscardsLateWrite(t *testing.T) {
+	a := assert.New(t)
+
+	handlerDone := make(chan struct{})
+	mux := NewErrorServeMux(StdErrorWriter)
+	mux.Use(Timeout(10 * time.Millisecond))
+	mux.HandleFunc("GET /slow", func(w http.ResponseWriter, r *http.Request) error {
+		defer close(handlerDone)
+
+		<-r.Context().Done()
+		// Simulate a handler that doesn't stop touching w as soon as the deadline passes,
+		// giving Timeout's own response time to win the race and commit first.
+		time.Sleep(20 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("late write"))
+		return nil
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/slow", nil)
+	rw := httptest.NewRecorder()
+	mux.ServeHTTP(rw, req)
+
+	<-handlerDone
+
+	a.Equal(http.StatusGatewayTimeout, rw.Result().StatusCode)
+	a.Equal("context deadline exceeded\n", rw.Body.String())
+}
+
+func TestRequestID(t *testing.T) {
+	a := assert.New(t)
+
+	var idFromContext string
+	mux := NewErrorServeMux(StdErrorWriter)
+	mux.Use(RequestID())
+	mux.HandleFunc("GET /id", func(w http.ResponseWriter, r *http.Request) error {
+		idFromContext, _ = RequestIDFromContext(r.Context())
+		return nil
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/id", nil)
+	rw := httptest.NewRecorder()
+	mux.ServeHTTP(rw, req)
+
+	a.NotEmpty(rw.Header().Get("X-Request-Id"))
+	a.Equal(rw.Header().Get("X-Request-Id"), idFromContext)
+}
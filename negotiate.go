@@ -0,0 +1,128 @@
+package httperr
+
+import (
+	"html"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// HtmlErrorWriter writes a minimal "text/html" error response. The body is built from
+// [PublicMessage], not err.Error(), so the raw error is never leaked to clients.
+func HtmlErrorWriter(w http.ResponseWriter, err error, code int) {
+	if handleHttpError(w, err, code, "text/html; charset=utf-8", encodeHTML) {
+		return
+	}
+
+	h := w.Header()
+	h.Del("Content-Length")
+	h.Set("Content-Type", "text/html; charset=utf-8")
+	h.Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(code)
+	_, _ = w.Write([]byte("<!doctype html><title>" + html.EscapeString(http.StatusText(code)) +
+		"</title><p>" + html.EscapeString(PublicMessage(err)) + "</p>\n"))
+}
+
+// NegotiatingErrorWriter returns an ErrorWriterRequest that picks the best-matching writer
+// from writers (keyed by media type, e.g. "application/json") based on the request's Accept
+// header, honoring q-values and "*/*" / "type/*" wildcards. fallback is used when none of the
+// registered media types are acceptable, or when the request has no Accept header.
+func NegotiatingErrorWriter(writers map[string]ErrorWriter, fallback ErrorWriter) ErrorWriterRequest {
+	return func(w http.ResponseWriter, r *http.Request, err error, code int) {
+		if ew := selectErrorWriter(writers, r.Header.Get("Accept")); ew != nil {
+			ew(w, err, code)
+			return
+		}
+		fallback(w, err, code)
+	}
+}
+
+type acceptRange struct {
+	typ, subtype string
+	q            float64
+}
+
+// selectErrorWriter returns the writer registered under the media type that best satisfies
+// accept, or nil when nothing in writers is acceptable.
+func selectErrorWriter(writers map[string]ErrorWriter, accept string) ErrorWriter {
+	if accept == "" {
+		return nil
+	}
+
+	var best ErrorWriter
+	bestQ := -1.0
+	bestSpecificity := -1
+
+	for _, rng := range parseAccept(accept) {
+		if rng.q <= 0 {
+			continue
+		}
+
+		for mediaType, ew := range writers {
+			typ, subtype, ok := strings.Cut(mediaType, "/")
+			if !ok {
+				continue
+			}
+
+			specificity, ok := matchAcceptRange(rng, typ, subtype)
+			if !ok {
+				continue
+			}
+
+			if rng.q > bestQ || (rng.q == bestQ && specificity > bestSpecificity) {
+				best, bestQ, bestSpecificity = ew, rng.q, specificity
+			}
+		}
+	}
+
+	return best
+}
+
+// matchAcceptRange reports whether rng accepts typ/subtype, and how specific the match is
+// (exact match ranks above a subtype wildcard, which ranks above a full wildcard).
+func matchAcceptRange(rng acceptRange, typ, subtype string) (specificity int, ok bool) {
+	switch {
+	case rng.typ == typ && rng.subtype == subtype:
+		return 2, true
+	case rng.typ == typ && rng.subtype == "*":
+		return 1, true
+	case rng.typ == "*" && rng.subtype == "*":
+		return 0, true
+	default:
+		return 0, false
+	}
+}
+
+// parseAccept parses an Accept header into its media ranges, ordered by descending q-value.
+func parseAccept(header string) []acceptRange {
+	parts := strings.Split(header, ",")
+	ranges := make([]acceptRange, 0, len(parts))
+
+	for _, part := range parts {
+		segments := strings.Split(part, ";")
+		typ, subtype, ok := strings.Cut(strings.TrimSpace(segments[0]), "/")
+		if !ok {
+			continue
+		}
+
+		q := 1.0
+		for _, seg := range segments[1:] {
+			seg = strings.TrimSpace(seg)
+			if !strings.HasPrefix(seg, "q=") {
+				continue
+			}
+			if parsed, err := strconv.ParseFloat(strings.TrimPrefix(seg, "q="), 64); err == nil {
+				q = parsed
+			}
+		}
+
+		ranges = append(ranges, acceptRange{typ: typ, subtype: subtype, q: q})
+	}
+
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].q > ranges[j].q
+	})
+
+	return ranges
+}
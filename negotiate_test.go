@@ -0,0 +1,98 @@
+package httperr
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHtmlErrorWriter(t *testing.T) {
+	a := assert.New(t)
+
+	rw := httptest.NewRecorder()
+	err := Public(errors.New("dial tcp 10.0.0.5:5432: connect: connection refused"), "the service is temporarily unavailable")
+
+	HtmlErrorWriter(rw, err, http.StatusServiceUnavailable)
+
+	a.Contains(rw.Body.String(), "the service is temporarily unavailable")
+	a.NotContains(rw.Body.String(), "10.0.0.5")
+}
+
+func TestNegotiatingErrorWriter(t *testing.T) {
+	writers := map[string]ErrorWriter{
+		"application/json":         JsonErrorWriter,
+		"application/problem+json": ProblemJsonErrorWriter,
+		"text/html":                HtmlErrorWriter,
+	}
+	negotiate := NegotiatingErrorWriter(writers, StdErrorWriter)
+
+	t.Run("exact match", func(t *testing.T) {
+		a := assert.New(t)
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Accept", "application/json")
+		rw := httptest.NewRecorder()
+
+		negotiate(rw, req, errors.New("standard error"), http.StatusInternalServerError)
+		a.Equal("application/json; charset=utf-8", rw.Header().Get("Content-Type"))
+	})
+
+	t.Run("q-value ranking", func(t *testing.T) {
+		a := assert.New(t)
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Accept", "application/json;q=0.5, application/problem+json;q=0.9")
+		rw := httptest.NewRecorder()
+
+		negotiate(rw, req, errors.New("standard error"), http.StatusInternalServerError)
+		a.Equal("application/problem+json; charset=utf-8", rw.Header().Get("Content-Type"))
+	})
+
+	t.Run("wildcard subtype", func(t *testing.T) {
+		a := assert.New(t)
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Accept", "text/*")
+		rw := httptest.NewRecorder()
+
+		negotiate(rw, req, errors.New("standard error"), http.StatusInternalServerError)
+		a.Equal("text/html; charset=utf-8", rw.Header().Get("Content-Type"))
+	})
+
+	t.Run("no acceptable writer falls back", func(t *testing.T) {
+		a := assert.New(t)
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Accept", "image/png")
+		rw := httptest.NewRecorder()
+
+		negotiate(rw, req, errors.New("standard error"), http.StatusInternalServerError)
+		a.Equal("text/plain; charset=utf-8", rw.Header().Get("Content-Type"))
+	})
+
+	t.Run("missing accept header falls back", func(t *testing.T) {
+		a := assert.New(t)
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		rw := httptest.NewRecorder()
+
+		negotiate(rw, req, errors.New("standard error"), http.StatusInternalServerError)
+		a.Equal("text/plain; charset=utf-8", rw.Header().Get("Content-Type"))
+	})
+}
+
+func TestErrorServeMuxWithRequest(t *testing.T) {
+	a := assert.New(t)
+
+	mux := NewErrorServeMuxWithRequest(NegotiatingErrorWriter(map[string]ErrorWriter{
+		"application/json": JsonErrorWriter,
+	}, StdErrorWriter))
+	mux.HandleFunc("GET /standard", func(w http.ResponseWriter, r *http.Request) error {
+		return errors.New("standard error")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/standard", nil)
+	req.Header.Set("Accept", "application/json")
+	rw := httptest.NewRecorder()
+
+	mux.ServeHTTP(rw, req)
+	a.Equal("application/json; charset=utf-8", rw.Header().Get("Content-Type"))
+}
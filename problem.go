@@ -0,0 +1,115 @@
+package httperr
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// Problem represents an RFC 7807 "Problem Details for HTTP APIs" payload.
+//
+// https://datatracker.ietf.org/doc/html/rfc7807
+type Problem struct {
+	// Type is a URI reference that identifies the problem type. Defaults to "about:blank" when empty.
+	Type string `json:"type,omitempty"`
+
+	// Title is a short, human-readable summary of the problem type.
+	Title string `json:"title,omitempty"`
+
+	// Status is the HTTP status code generated by the origin server for this occurrence of the problem.
+	Status int `json:"status,omitempty"`
+
+	// Detail is a human-readable explanation specific to this occurrence of the problem.
+	Detail string `json:"detail,omitempty"`
+
+	// Instance is a URI reference that identifies the specific occurrence of the problem.
+	Instance string `json:"instance,omitempty"`
+
+	// Extensions holds additional members that are merged into the top-level JSON object.
+	Extensions map[string]any `json:"-"`
+}
+
+// MarshalJSON merges Extensions into the top-level problem object, as required by RFC 7807.
+func (p Problem) MarshalJSON() ([]byte, error) {
+	out := make(map[string]any, len(p.Extensions)+5)
+	for k, v := range p.Extensions {
+		out[k] = v
+	}
+
+	if p.Type != "" {
+		out["type"] = p.Type
+	}
+	if p.Title != "" {
+		out["title"] = p.Title
+	}
+	if p.Status != 0 {
+		out["status"] = p.Status
+	}
+	if p.Detail != "" {
+		out["detail"] = p.Detail
+	}
+	if p.Instance != "" {
+		out["instance"] = p.Instance
+	}
+
+	return json.Marshal(out)
+}
+
+// ProblemDetailer is implemented by errors that carry their own RFC 7807 [Problem].
+// When an error implements ProblemDetailer, ProblemJsonErrorWriter preserves every field as-is.
+type ProblemDetailer interface {
+	error
+	Problem() Problem
+}
+
+type problemError struct {
+	error
+	problem Problem
+}
+
+func (e *problemError) StatusCode() int {
+	return e.problem.Status
+}
+
+func (e *problemError) Problem() Problem {
+	return e.problem
+}
+
+func (e *problemError) Unwrap() error {
+	return e.error
+}
+
+// WithProblem embeds an RFC 7807 [Problem] in the original error.
+// When caught by the handler, ProblemJsonErrorWriter will render the problem as-is.
+func WithProblem(err error, problem Problem) error {
+	return &problemError{err, problem}
+}
+
+// ProblemJsonErrorWriter writes an "application/problem+json" response per RFC 7807.
+//
+// If err implements [ProblemDetailer] its [Problem] is rendered unchanged. Otherwise a Problem
+// is synthesized: Title from http.StatusText(code) and Detail from [PublicMessage], so the raw
+// err.Error() is never leaked to clients.
+func ProblemJsonErrorWriter(w http.ResponseWriter, err error, code int) {
+	if handleHttpError(w, err, code, "application/problem+json; charset=utf-8", encodeJSON) {
+		return
+	}
+
+	problem := Problem{
+		Title:  http.StatusText(code),
+		Status: code,
+		Detail: PublicMessage(err),
+	}
+
+	var pd ProblemDetailer
+	if errors.As(err, &pd) {
+		problem = pd.Problem()
+	}
+
+	h := w.Header()
+	h.Del("Content-Length")
+	h.Set("Content-Type", "application/problem+json; charset=utf-8")
+	h.Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(problem)
+}
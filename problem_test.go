@@ -0,0 +1,99 @@
+package httperr
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProblemMarshalJSON(t *testing.T) {
+	a := assert.New(t)
+
+	p := Problem{
+		Type:   "https://example.com/probs/out-of-credit",
+		Title:  "You do not have enough credit.",
+		Status: 403,
+		Detail: "Your current balance is 30, but that costs 50.",
+		Extensions: map[string]any{
+			"balance": 30,
+		},
+	}
+
+	data, err := json.Marshal(p)
+	a.NoError(err)
+
+	var decoded map[string]any
+	a.NoError(json.Unmarshal(data, &decoded))
+	a.Equal("https://example.com/probs/out-of-credit", decoded["type"])
+	a.Equal("You do not have enough credit.", decoded["title"])
+	a.Equal(float64(403), decoded["status"])
+	a.Equal("Your current balance is 30, but that costs 50.", decoded["detail"])
+	a.Equal(float64(30), decoded["balance"])
+}
+
+func TestWithProblem(t *testing.T) {
+	a := assert.New(t)
+
+	err := WithProblem(errors.New("insufficient funds"), Problem{
+		Status: http.StatusForbidden,
+		Title:  "Out of credit",
+	})
+
+	a.Equal(http.StatusForbidden, HTTPStatus(err))
+
+	var pd ProblemDetailer
+	a.True(errors.As(err, &pd))
+	a.Equal("Out of credit", pd.Problem().Title)
+}
+
+func TestProblemJsonErrorWriter(t *testing.T) {
+	a := assert.New(t)
+
+	t.Run("synthesized from plain error is redacted", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+		ProblemJsonErrorWriter(rw, errors.New("standard error"), http.StatusInternalServerError)
+
+		a.Equal(http.StatusInternalServerError, rw.Result().StatusCode)
+		a.Equal("application/problem+json; charset=utf-8", rw.Header().Get("Content-Type"))
+		a.Equal("nosniff", rw.Header().Get("X-Content-Type-Options"))
+
+		var decoded map[string]any
+		a.NoError(json.Unmarshal(rw.Body.Bytes(), &decoded))
+		a.Equal("Internal Server Error", decoded["title"])
+		a.Equal("Internal Server Error", decoded["detail"])
+		a.Equal(float64(500), decoded["status"])
+	})
+
+	t.Run("synthesized detail uses the public message, not the raw error", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+		err := Public(errors.New("dial tcp 10.0.0.5:5432: connect: connection refused"), "the service is temporarily unavailable")
+
+		ProblemJsonErrorWriter(rw, err, http.StatusServiceUnavailable)
+
+		var decoded map[string]any
+		a.NoError(json.Unmarshal(rw.Body.Bytes(), &decoded))
+		a.Equal("the service is temporarily unavailable", decoded["detail"])
+		a.NotContains(rw.Body.String(), "10.0.0.5")
+	})
+
+	t.Run("preserved from ProblemDetailer", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+		err := WithProblem(errors.New("insufficient funds"), Problem{
+			Type:   "https://example.com/probs/out-of-credit",
+			Title:  "Out of credit",
+			Status: http.StatusForbidden,
+		})
+
+		ProblemJsonErrorWriter(rw, err, http.StatusForbidden)
+
+		var decoded map[string]any
+		a.NoError(json.Unmarshal(rw.Body.Bytes(), &decoded))
+		a.Equal("https://example.com/probs/out-of-credit", decoded["type"])
+		a.Equal("Out of credit", decoded["title"])
+		a.Nil(decoded["detail"])
+	})
+}